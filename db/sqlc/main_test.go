@@ -16,6 +16,7 @@ const (
 )
 
 var testQueries *Queries // Will define a testQueries object(contains a DBTX which is db conn or Tx) as a global variable
+var testDB *sql.DB       // Raw connection, kept around so tests can build a Store with NewStore(testDB)
 
 /*
  * The main entry point of all unit tests inside 1 specific Golang package (package db)
@@ -27,6 +28,7 @@ func TestMain(m *testing.M) {
 		log.Fatal("cannot connect to db:", err)
 	}
 
+	testDB = conn
 	testQueries = New(conn) // Use a connection to create a new testQueries object
 
 	// m.Run(): To start running the Unit test which will return an 'exit code'(test pass or fail)
@@ -0,0 +1,369 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+)
+
+// MemStore is an in-memory Store implementation backed by plain maps + a sync.RWMutex. Every
+// exported Querier method takes store.mu itself, so MemStore is safe to call directly from
+// several goroutines as well as from inside ExecTx. It honors ExecTx's transactional
+// semantics by snapshotting its state when a transaction starts and discarding the snapshot
+// (rolling back) if the callback returns an error. It's meant for fast unit tests of the
+// API/service layer that don't want to stand up a real Postgres instance.
+type MemStore struct {
+	mu sync.RWMutex
+
+	accounts     map[int64]Account
+	entries      map[int64]Entry
+	transfers    map[int64]Transfer
+	outboxEvents map[int64]OutboxEvent
+
+	nextAccountID     int64
+	nextEntryID       int64
+	nextTransferID    int64
+	nextOutboxEventID int64
+}
+
+// NewMemStore creates an empty MemStore, returned as a Store so it's a drop-in replacement
+// for NewStore in tests.
+func NewMemStore() Store {
+	return &MemStore{
+		accounts:     make(map[int64]Account),
+		entries:      make(map[int64]Entry),
+		transfers:    make(map[int64]Transfer),
+		outboxEvents: make(map[int64]OutboxEvent),
+	}
+}
+
+// snapshot is a deep-enough copy of MemStore's maps to restore on rollback. Account/Entry/
+// Transfer/OutboxEvent are plain value structs, so copying the maps is sufficient.
+type memSnapshot struct {
+	accounts     map[int64]Account
+	entries      map[int64]Entry
+	transfers    map[int64]Transfer
+	outboxEvents map[int64]OutboxEvent
+
+	nextAccountID     int64
+	nextEntryID       int64
+	nextTransferID    int64
+	nextOutboxEventID int64
+}
+
+// snapshot and restore assume the caller already holds store.mu.
+func (store *MemStore) snapshot() memSnapshot {
+	snap := memSnapshot{
+		accounts:          make(map[int64]Account, len(store.accounts)),
+		entries:           make(map[int64]Entry, len(store.entries)),
+		transfers:         make(map[int64]Transfer, len(store.transfers)),
+		outboxEvents:      make(map[int64]OutboxEvent, len(store.outboxEvents)),
+		nextAccountID:     store.nextAccountID,
+		nextEntryID:       store.nextEntryID,
+		nextTransferID:    store.nextTransferID,
+		nextOutboxEventID: store.nextOutboxEventID,
+	}
+	for id, account := range store.accounts {
+		snap.accounts[id] = account
+	}
+	for id, entry := range store.entries {
+		snap.entries[id] = entry
+	}
+	for id, transfer := range store.transfers {
+		snap.transfers[id] = transfer
+	}
+	for id, event := range store.outboxEvents {
+		snap.outboxEvents[id] = event
+	}
+	return snap
+}
+
+func (store *MemStore) restore(snap memSnapshot) {
+	store.accounts = snap.accounts
+	store.entries = snap.entries
+	store.transfers = snap.transfers
+	store.outboxEvents = snap.outboxEvents
+	store.nextAccountID = snap.nextAccountID
+	store.nextEntryID = snap.nextEntryID
+	store.nextTransferID = snap.nextTransferID
+	store.nextOutboxEventID = snap.nextOutboxEventID
+}
+
+// ExecTx runs fn against a Querier bound to this MemStore. It takes store.mu for the whole
+// call, snapshots the current state, and hands fn a memTxQuerier, which accesses the maps
+// directly instead of re-taking store.mu like the exported Querier methods do - fn's calls
+// already run under this single lock acquisition. If fn returns an error, the snapshot is
+// restored so none of fn's writes are observed, mirroring a real ROLLBACK. opts is accepted to
+// satisfy the Store interface but otherwise ignored: there's no isolation level to pick and
+// nothing to retry when every access is already serialized behind store.mu.
+func (store *MemStore) ExecTx(ctx context.Context, opts *TxOptions, fn func(Querier) error) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	snap := store.snapshot()
+	if err := fn(&memTxQuerier{store: store}); err != nil {
+		store.restore(snap)
+		return err
+	}
+	return nil
+}
+
+// TransferTx mirrors SQLStore.TransferTx so MemStore can stand in for Store in tests that
+// exercise the transfer flow.
+func (store *MemStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	return store.transferTx(ctx, arg)
+}
+
+// TransferTxSerializable mirrors SQLStore.TransferTxSerializable. MemStore has no concept of
+// isolation levels or serialization failures, so it's just an alias for TransferTx.
+func (store *MemStore) TransferTxSerializable(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	return store.transferTx(ctx, arg)
+}
+
+func (store *MemStore) transferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.ExecTx(ctx, nil, func(q Querier) error {
+		var err error
+
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: arg.FromAccountID,
+			Amount:    -arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: arg.ToAccountID,
+			Amount:    arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.FromAccountID < arg.ToAccountID {
+			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
+		} else {
+			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		return insertTransferCompletedEvent(ctx, q, result.Transfer)
+	})
+
+	return result, err
+}
+
+func (store *MemStore) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.createAccount(arg)
+}
+
+func (store *MemStore) createAccount(arg CreateAccountParams) (Account, error) {
+	store.nextAccountID++
+	account := Account{
+		ID:       store.nextAccountID,
+		Owner:    arg.Owner,
+		Balance:  arg.Balance,
+		Currency: arg.Currency,
+	}
+	store.accounts[account.ID] = account
+	return account, nil
+}
+
+func (store *MemStore) GetAccount(ctx context.Context, id int64) (Account, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return store.getAccount(id)
+}
+
+func (store *MemStore) getAccount(id int64) (Account, error) {
+	account, ok := store.accounts[id]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	return account, nil
+}
+
+func (store *MemStore) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.updateAccount(arg)
+}
+
+func (store *MemStore) updateAccount(arg UpdateAccountParams) (Account, error) {
+	account, ok := store.accounts[arg.ID]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	account.Balance = arg.Balance
+	store.accounts[account.ID] = account
+	return account, nil
+}
+
+func (store *MemStore) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.addAccountBalance(arg)
+}
+
+func (store *MemStore) addAccountBalance(arg AddAccountBalanceParams) (Account, error) {
+	account, ok := store.accounts[arg.ID]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	account.Balance += arg.Amount
+	store.accounts[account.ID] = account
+	return account, nil
+}
+
+func (store *MemStore) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.createEntry(arg)
+}
+
+func (store *MemStore) createEntry(arg CreateEntryParams) (Entry, error) {
+	store.nextEntryID++
+	entry := Entry{
+		ID:        store.nextEntryID,
+		AccountID: arg.AccountID,
+		Amount:    arg.Amount,
+	}
+	store.entries[entry.ID] = entry
+	return entry, nil
+}
+
+func (store *MemStore) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.createTransfer(arg)
+}
+
+func (store *MemStore) createTransfer(arg CreateTransferParams) (Transfer, error) {
+	store.nextTransferID++
+	transfer := Transfer{
+		ID:            store.nextTransferID,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+	}
+	store.transfers[transfer.ID] = transfer
+	return transfer, nil
+}
+
+func (store *MemStore) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.createOutboxEvent(arg)
+}
+
+func (store *MemStore) createOutboxEvent(arg CreateOutboxEventParams) (OutboxEvent, error) {
+	store.nextOutboxEventID++
+	event := OutboxEvent{
+		ID:        store.nextOutboxEventID,
+		EventType: arg.EventType,
+		Payload:   arg.Payload,
+	}
+	store.outboxEvents[event.ID] = event
+	return event, nil
+}
+
+// ListPendingOutboxEvents returns unpublished events ordered by ID, which in MemStore stands
+// in for ORDER BY created_at since IDs are handed out in insertion order.
+func (store *MemStore) ListPendingOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return store.listPendingOutboxEvents(limit)
+}
+
+func (store *MemStore) listPendingOutboxEvents(limit int32) ([]OutboxEvent, error) {
+	pending := make([]OutboxEvent, 0, len(store.outboxEvents))
+	for _, event := range store.outboxEvents {
+		if !event.Published {
+			pending = append(pending, event)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+
+	if int32(len(pending)) > limit {
+		pending = pending[:limit]
+	}
+	return pending, nil
+}
+
+func (store *MemStore) MarkOutboxEventPublished(ctx context.Context, id int64) (OutboxEvent, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.markOutboxEventPublished(id)
+}
+
+func (store *MemStore) markOutboxEventPublished(id int64) (OutboxEvent, error) {
+	event, ok := store.outboxEvents[id]
+	if !ok {
+		return OutboxEvent{}, sql.ErrNoRows
+	}
+	event.Published = true
+	store.outboxEvents[event.ID] = event
+	return event, nil
+}
+
+// memTxQuerier implements Querier against a MemStore whose store.mu is already held by the
+// enclosing ExecTx call. Its methods call the unexported, lock-free helpers directly instead of
+// going through the exported MemStore methods, which would try to re-take store.mu and deadlock.
+type memTxQuerier struct {
+	store *MemStore
+}
+
+func (q *memTxQuerier) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	return q.store.createAccount(arg)
+}
+
+func (q *memTxQuerier) GetAccount(ctx context.Context, id int64) (Account, error) {
+	return q.store.getAccount(id)
+}
+
+func (q *memTxQuerier) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	return q.store.updateAccount(arg)
+}
+
+func (q *memTxQuerier) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	return q.store.addAccountBalance(arg)
+}
+
+func (q *memTxQuerier) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	return q.store.createEntry(arg)
+}
+
+func (q *memTxQuerier) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	return q.store.createTransfer(arg)
+}
+
+func (q *memTxQuerier) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error) {
+	return q.store.createOutboxEvent(arg)
+}
+
+func (q *memTxQuerier) ListPendingOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error) {
+	return q.store.listPendingOutboxEvents(limit)
+}
+
+func (q *memTxQuerier) MarkOutboxEventPublished(ctx context.Context, id int64) (OutboxEvent, error) {
+	return q.store.markOutboxEventPublished(id)
+}
+
+var _ Querier = (*memTxQuerier)(nil)
+var _ Store = (*MemStore)(nil)
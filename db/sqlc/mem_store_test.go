@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemStoreTransferTx(t *testing.T) {
+	store := NewMemStore()
+	ctx := context.Background()
+
+	account1, err := store.CreateAccount(ctx, CreateAccountParams{Owner: "alice", Balance: 100, Currency: "USD"})
+	require.NoError(t, err)
+
+	account2, err := store.CreateAccount(ctx, CreateAccountParams{Owner: "bob", Balance: 50, Currency: "USD"})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), result.FromAccount.Balance)
+	require.Equal(t, int64(80), result.ToAccount.Balance)
+
+	updated1, err := store.GetAccount(ctx, account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(70), updated1.Balance)
+}
+
+func TestMemStoreExecTxRollback(t *testing.T) {
+	store := NewMemStore()
+	ctx := context.Background()
+
+	account, err := store.CreateAccount(ctx, CreateAccountParams{Owner: "alice", Balance: 100, Currency: "USD"})
+	require.NoError(t, err)
+
+	boom := errors.New("boom")
+	err = store.ExecTx(ctx, nil, func(q Querier) error {
+		if _, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{ID: account.ID, Amount: 1000}); err != nil {
+			return err
+		}
+		return boom
+	})
+	require.ErrorIs(t, err, boom)
+
+	// The balance update above must be invisible: the snapshot taken on ExecTx entry is
+	// restored because the callback returned an error.
+	unchanged, err := store.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), unchanged.Balance)
+}
+
+// TestMemStoreConcurrentQuerierAccess calls AddAccountBalance and GetAccount directly (not
+// wrapped in ExecTx) from many goroutines at once - the normal way to call a Querier, and how
+// worker.OutboxDispatcher used to call ListPendingOutboxEvents/MarkOutboxEventPublished before
+// every MemStore method took store.mu. Run with -race: it must come back clean.
+func TestMemStoreConcurrentQuerierAccess(t *testing.T) {
+	store := NewMemStore()
+	ctx := context.Background()
+
+	account, err := store.CreateAccount(ctx, CreateAccountParams{Owner: "alice", Balance: 0, Currency: "USD"})
+	require.NoError(t, err)
+
+	const goroutines = 20
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			_, err := store.AddAccountBalance(ctx, AddAccountBalanceParams{ID: account.ID, Amount: 1})
+			require.NoError(t, err)
+			_, err = store.GetAccount(ctx, account.ID)
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	updated, err := store.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(goroutines), updated.Balance)
+}
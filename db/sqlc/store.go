@@ -3,51 +3,127 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
 )
 
-// Store defines all functions to run db queries individually and their combination within a transaction
-type Store struct {
+// Store defines all functions to run db queries individually and their combination within a
+// transaction. It embeds Querier rather than *Queries so that callers (and tests) can depend on
+// the interface and swap in a MemStore instead of talking to a real Postgres instance.
+type Store interface {
+	Querier
+	ExecTx(ctx context.Context, opts *TxOptions, fn func(Querier) error) error
+	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	TransferTxSerializable(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+}
+
+// SQLStore is the Store implementation backed by a real *sql.DB. It's what NewStore returns
+// in production; MemStore (in mem_store.go) is the in-memory double used by fast unit tests.
+type SQLStore struct {
 	// For individual Queries, we already have Queries struct, but each query only does 1 operation on 1 specific table
 	// => Queries struct doesn't support Transaction => so, have to extend its functionality by embedding it inside
 	// the Store struct like below(called a Composition: a preferred way to extend struct functionality instead of Inheritance)
-	db       *sql.DB // All individual query functions provided by Queries'll be available to Store => can support TX by adding more funcs to that new struct
-	*Queries         // In order to do above, Store needs to have sql.DB obj cuz it's required to create a new db TX
+	*Queries
+	transactor *Transactor
 }
 
-// NewStore() to create a new store obj
-func NewStore(db *sql.DB) *Store {
-	return &Store{ // Just build a new store obj and return it
-		db:      db,      // db is the input sql.DB
-		Queries: New(db), // Queries is created by calling the New() with that db object, New is created by sqlc and it'll return a Queries obj
+// NewStore() to create a new SQLStore obj, returned as a Store so callers depend on the interface
+func NewStore(db *sql.DB) Store {
+	return &SQLStore{ // Just build a new store obj and return it
+		Queries:    New(db), // Queries is created by calling the New() with that db object, New is created by sqlc and it'll return a Queries obj
+		transactor: NewTransactor(db),
 	}
 }
 
-// To execute a generic database transaction
-// This func is unexported cuz it starts with a lowercase letter => don't want external pkg call it directly, will provide an exported func for each specific TX instead
-// - Takes a context, and a callback function as input. Then it'll start a new db TX
-// In sum: it creates a new Queries obj with that TX, and call the callback function with the created Queries
-//
-//	and finally commit or rollback the TX based on the error returned by that function.
-func (store *Store) execTx(ctx context.Context, fn func(*Queries) error) error {
-	// &sql.TxOptions{}: optional, allows us to set a custom isolation level for this TX
-	// if we don't set it explicitly, then the default isolation level of the DB Server will be used (= read-committed in case of Postgres)
-	// tx, err := store.db.BeginTx(ctx, &sql.TxOptions{})
-	tx, err := store.db.BeginTx(ctx, nil) // To start a new transaction, nil to use default value. BeginTx returns a TX obj/error
-	if err != nil {
-		return err
+// RunInTx delegates to the store's Transactor, letting service-layer code compose several
+// store.Q(ctx) calls into one transaction without threading *Queries through every call.
+func (store *SQLStore) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return store.transactor.RunInTx(ctx, nil, fn)
+}
+
+// Q returns the *Queries to use for ctx: tx-bound if a RunInTx call is in progress on ctx,
+// or DB-bound otherwise. Callers do `q := store.Q(ctx)` right before each repository call
+// instead of threading a *Queries value through every function signature.
+func (store *SQLStore) Q(ctx context.Context) *Queries {
+	if tx := txFromContext(ctx); tx != nil {
+		return New(tx)
+	}
+	return store.Queries
+}
+
+// TxOptions configures how ExecTx runs its transaction: the isolation level and read-only
+// flag are passed straight through to sql.DB.BeginTx; MaxAttempts controls how many times the
+// callback is retried if Postgres reports a transient serialization failure (SQLSTATE 40001)
+// or deadlock (40P01). A nil *TxOptions means "use the defaults" (see defaultTxOptions).
+type TxOptions struct {
+	Isolation   sql.IsolationLevel
+	ReadOnly    bool
+	MaxAttempts int // 0 or 1 means "do not retry"
+}
+
+func defaultTxOptions() *TxOptions {
+	return &TxOptions{Isolation: sql.LevelDefault, MaxAttempts: 1}
+}
+
+// ExecTx executes a generic database transaction, retrying the callback when opts requests
+// it and Postgres reports that the transaction can safely be retried. It's built directly on
+// top of store.transactor.RunInTx and store.Q(ctx): fn runs against store.Q(ctx), the same
+// *Queries a RunInTx/Q(ctx) caller elsewhere would get for that same ctx. That's what lets
+// TransferTx's outbox insert (the "account + outbox in one transaction" case) and any
+// store.RunInTx-based service code share one transaction mechanism instead of two.
+// - Takes a context, tx options, and a callback function as input. Then it'll start a new db TX
+// In sum: it runs fn against the Queries bound to that TX, and commits or rolls back based on
+// the error fn returns.
+func (store *SQLStore) ExecTx(ctx context.Context, opts *TxOptions, fn func(Querier) error) error {
+	if opts == nil {
+		opts = defaultTxOptions()
 	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	txOpts := &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly}
 
-	q := New(tx)    // Instead of passing in sql.DB, now pass in sql.Tx object (this works cuz the New() accepts a DBTX interface)
-	err = fn(q)     // Now we have the Queries that runs within TX => we call the input function with that query and get back an error
-	if err != nil { // Rollback the TX if we have an error, also return rollback error
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr) // Report 2 errors if we also have Rollback Error => combine them into 1 Error to return
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = store.transactor.RunInTx(ctx, txOpts, func(ctx context.Context) error {
+			return fn(store.Q(ctx))
+		})
+		if err == nil || attempt == maxAttempts || !isRetryableTxError(err) {
+			return err
 		}
-		return err // if the Rollback is successful, return the original transaction error
+		time.Sleep(retryBackoff(attempt))
+	}
+	return err
+}
+
+// isRetryableTxError reports whether err is a Postgres error that a transaction may safely be
+// retried after: serialization_failure (40001, raised under SERIALIZABLE isolation) or
+// deadlock_detected (40P01).
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code {
+	case "40001", "40P01":
+		return true
+	default:
+		return false
 	}
+}
 
-	return tx.Commit() // If all operations in TX are successful, commit TX and retuns its error to the Caller.
+// retryBackoff returns an exponential backoff delay for the given attempt number (1-indexed),
+// plus random jitter, so that retrying transactions don't all collide again on the next try.
+func retryBackoff(attempt int) time.Duration {
+	base := 20 * time.Millisecond * time.Duration(1<<uint(attempt-1))
+	jitter := time.Duration(rand.Int63n(int64(base) + 1))
+	return base + jitter
 }
 
 // TransferTxParams contains all necessary input parameters to transfer money between 2 accounts
@@ -71,10 +147,22 @@ var txKey = struct{}{} // The second bracket (creating a "new empty obj of that
 
 // TransferTx performs a money transfer from one account to the other.
 // It creates a transfer record, add new account entries, update accounts' balance within a single database transaction
-func (store *Store) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	return store.transferTx(ctx, nil, arg)
+}
+
+// TransferTxSerializable runs TransferTx at sql.LevelSerializable, retrying the whole
+// transaction with backoff if Postgres aborts it with a serialization failure. Use this
+// instead of TransferTx when the caller needs a strict guarantee against the read skew that
+// read-committed (TransferTx's default) allows.
+func (store *SQLStore) TransferTxSerializable(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	return store.transferTx(ctx, &TxOptions{Isolation: sql.LevelSerializable, MaxAttempts: 5}, arg)
+}
+
+func (store *SQLStore) transferTx(ctx context.Context, opts *TxOptions, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult // Create an empty result
 
-	err := store.execTx(ctx, func(q *Queries) error { // To create and run a new DB TX, pass in context and callback function
+	err := store.ExecTx(ctx, opts, func(q Querier) error { // To create and run a new TX (real or in-memory), pass in context and callback function
 		/* Step 1: Create a transfer record */
 		var err error
 
@@ -117,35 +205,23 @@ func (store *Store) TransferTx(ctx context.Context, arg TransferTxParams) (Trans
 		}
 
 		/********* Step 3: update accounts' balance *********/
-		// Moving the Money out of the fromAccount
-		fmt.Println(txName, "get account 1")
-		account1, err := q.GetAccount(ctx, arg.FromAccountID)
-		if err != nil {
-			return err
+		// Update the accounts in a fixed order (smaller ID first) regardless of which one is
+		// the sender, so two concurrent transfers between the same pair of accounts (A->B and
+		// B->A) always acquire their row locks in the same order and can never deadlock.
+		fmt.Println(txName, "update balances")
+		if arg.FromAccountID < arg.ToAccountID {
+			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
+		} else {
+			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
 		}
-
-		fmt.Println(txName, "update account 1")
-		result.FromAccount, err = q.UpdateAccount(ctx, UpdateAccountParams{
-			ID:      arg.FromAccountID,
-			Balance: account1.Balance - arg.Amount,
-		})
 		if err != nil {
 			return err
 		}
 
-		// Do similar thing to move those money into the toAccount
-		fmt.Println(txName, "get account 2")
-		account2, err := q.GetAccount(ctx, arg.ToAccountID)
-		if err != nil {
-			return err
-		}
-
-		fmt.Println(txName, "update account 2")
-		result.ToAccount, err = q.UpdateAccount(ctx, UpdateAccountParams{
-			ID:      arg.ToAccountID,
-			Balance: account2.Balance + arg.Amount,
-		})
-		if err != nil {
+		/* Step 4: record a transfer.completed outbox event in the same transaction, so that
+		 * event publishing is atomically consistent with the balance update without needing
+		 * a two-phase commit to the message broker. */
+		if err := insertTransferCompletedEvent(ctx, q, result.Transfer); err != nil {
 			return err
 		}
 
@@ -154,3 +230,58 @@ func (store *Store) TransferTx(ctx context.Context, arg TransferTxParams) (Trans
 
 	return result, err // Returns the result and error of the execTx() call
 }
+
+// transferCompletedPayload is the JSON payload stored on the transfer.completed outbox event.
+type transferCompletedPayload struct {
+	TransferID    int64 `json:"transfer_id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+}
+
+// insertTransferCompletedEvent writes a transfer.completed outbox row for transfer using q, so
+// it lands in the same database transaction as the balance update it describes.
+func insertTransferCompletedEvent(ctx context.Context, q Querier, transfer Transfer) error {
+	payload, err := json.Marshal(transferCompletedPayload{
+		TransferID:    transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventType: "transfer.completed",
+		Payload:   payload,
+	})
+	return err
+}
+
+// addMoney adds amount1 to the balance of account1 and amount2 to the balance of account2,
+// using AddAccountBalance (UPDATE ... RETURNING *) instead of GetAccount+UpdateAccount so that
+// each row is locked and updated in a single statement. Callers must always pass accountID1 <
+// accountID2 so that concurrent transactions lock the accounts in the same order.
+func addMoney(
+	ctx context.Context,
+	q Querier,
+	accountID1 int64,
+	amount1 int64,
+	accountID2 int64,
+	amount2 int64,
+) (account1 Account, account2 Account, err error) {
+	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     accountID1,
+		Amount: amount1,
+	})
+	if err != nil {
+		return
+	}
+
+	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     accountID2,
+		Amount: amount2,
+	})
+	return
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// txCtxKey is the context.Context key under which Transactor stores the active *sql.Tx.
+type txCtxKey struct{}
+
+// Transactor threads a *sql.Tx through context.Context so service-layer code can compose
+// several repository calls (e.g. account + outbox + audit) into a single transaction without
+// passing a *Queries value through every function signature. Inspired by the oniontx/dbtx
+// "unit of work" pattern.
+type Transactor struct {
+	db *sql.DB
+}
+
+// NewTransactor creates a Transactor bound to db.
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// RunInTx runs fn with ctx carrying an active transaction opened with opts (nil means the
+// driver's default options). If ctx already carries a transaction - because an outer RunInTx
+// call is in progress - fn joins that transaction instead of opening a new one (opts is then
+// ignored), and this call's commit/rollback is a no-op; only the outermost RunInTx actually
+// commits or rolls back. This is the engine SQLStore.ExecTx itself runs on top of, via
+// store.Q(ctx), so that a plain ExecTx(Querier) callback and service-layer code doing
+// store.RunInTx/store.Q share the same transactions instead of two disjoint mechanisms.
+func (t *Transactor) RunInTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	if txFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	err = fn(context.WithValue(ctx, txCtxKey{}, tx))
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// txFromContext returns the *sql.Tx stashed by RunInTx, or nil if ctx carries none.
+func txFromContext(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(txCtxKey{}).(*sql.Tx)
+	return tx
+}
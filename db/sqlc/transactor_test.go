@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRunInTxCommits(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	ctx := context.Background()
+
+	var account1, account2 Account
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		q := store.Q(ctx)
+
+		var err error
+		account1, err = q.CreateAccount(ctx, CreateAccountParams{Owner: "uow-1", Balance: 100, Currency: "USD"})
+		if err != nil {
+			return err
+		}
+
+		account2, err = q.CreateAccount(ctx, CreateAccountParams{Owner: "uow-2", Balance: 200, Currency: "USD"})
+		return err
+	})
+	require.NoError(t, err)
+
+	got1, err := testQueries.GetAccount(ctx, account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1, got1)
+
+	got2, err := testQueries.GetAccount(ctx, account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2, got2)
+}
+
+func TestStoreRunInTxRollsBackOnError(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	ctx := context.Background()
+
+	boom := errors.New("boom")
+	var created Account
+
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		q := store.Q(ctx)
+
+		var err error
+		created, err = q.CreateAccount(ctx, CreateAccountParams{Owner: "uow-rollback", Balance: 100, Currency: "USD"})
+		if err != nil {
+			return err
+		}
+
+		return boom
+	})
+	require.ErrorIs(t, err, boom)
+
+	// The account created above must not have been committed.
+	_, err = testQueries.GetAccount(ctx, created.ID)
+	require.Error(t, err)
+}
+
+func TestStoreRunInTxNestedJoinsOuterTx(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	ctx := context.Background()
+
+	var account Account
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		// A nested RunInTx call must join the outer transaction rather than starting a new
+		// one, so its write is only visible once the outer call commits.
+		return store.RunInTx(ctx, func(ctx context.Context) error {
+			q := store.Q(ctx)
+
+			var err error
+			account, err = q.CreateAccount(ctx, CreateAccountParams{Owner: "uow-nested", Balance: 50, Currency: "USD"})
+			return err
+		})
+	})
+	require.NoError(t, err)
+
+	got, err := testQueries.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account, got)
+}
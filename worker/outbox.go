@@ -0,0 +1,103 @@
+// Package worker hosts background processes that run alongside the API server, starting with
+// the outbox dispatcher that publishes events written by db.Store.TransferTx.
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/0xThomas3000/simplebank/db/sqlc"
+)
+
+// Publisher hands a single outbox event off to wherever it needs to go next - a log line, a
+// Redis stream, a message broker. OutboxDispatcher only depends on this interface so the
+// transport can be swapped without touching the polling/marking logic.
+type Publisher interface {
+	Publish(ctx context.Context, event db.OutboxEvent) error
+}
+
+// OutboxDispatcher polls outbox_events for rows that haven't been published yet, hands each
+// one to a Publisher, and marks it published. Delivery is at-least-once: if the process dies
+// between Publish succeeding and MarkOutboxEventPublished committing, the same event is handed
+// to Publish again on the next poll, so every Publisher implementation must tolerate duplicates.
+type OutboxDispatcher struct {
+	store        db.Store
+	publisher    Publisher
+	pollInterval time.Duration
+	batchSize    int32
+}
+
+// NewOutboxDispatcher creates a dispatcher that polls store every pollInterval for up to
+// batchSize pending events at a time and hands them to publisher.
+func NewOutboxDispatcher(store db.Store, publisher Publisher, pollInterval time.Duration, batchSize int32) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		store:        store,
+		publisher:    publisher,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+	}
+}
+
+// Run polls on a ticker until ctx is canceled. Call it in its own goroutine, e.g.
+// `go dispatcher.Run(ctx)`.
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatchPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchPending runs a single poll: list pending events, publish each, mark it published, all
+// inside one ExecTx. That matters because ListPendingOutboxEvents is a `FOR UPDATE SKIP LOCKED`
+// query - its row locks are only held for the lifetime of the transaction that ran it. Listing
+// and marking as two separate, unwrapped calls would each run in their own implicit
+// single-statement transaction, releasing the lock the instant the SELECT returned and leaving
+// two dispatcher instances free to claim the same row. Running both in one ExecTx keeps the
+// locks held across the publish, so a second instance's SKIP LOCKED poll genuinely can't see
+// rows this one is still working on.
+//
+// A publish failure for one event is logged and skipped, leaving it pending for the next poll;
+// a failure marking an event published aborts the whole batch so the same transaction doesn't
+// partially commit, and every event in it is retried (Publish must tolerate duplicates).
+func (d *OutboxDispatcher) dispatchPending(ctx context.Context) {
+	err := d.store.ExecTx(ctx, nil, func(q db.Querier) error {
+		events, err := q.ListPendingOutboxEvents(ctx, d.batchSize)
+		if err != nil {
+			return fmt.Errorf("list pending events: %w", err)
+		}
+
+		for _, event := range events {
+			if err := d.publisher.Publish(ctx, event); err != nil {
+				log.Printf("outbox: failed to publish event %d: %v", event.ID, err)
+				continue
+			}
+
+			if _, err := q.MarkOutboxEventPublished(ctx, event.ID); err != nil {
+				return fmt.Errorf("mark event %d published: %w", event.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("outbox: dispatch failed: %v", err)
+	}
+}
+
+// LogPublisher publishes events by writing a log line. It's the simplest transport and is
+// useful in tests that only care that the dispatcher drained the outbox.
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(ctx context.Context, event db.OutboxEvent) error {
+	log.Printf("outbox: event %d (%s): %s", event.ID, event.EventType, event.Payload)
+	return nil
+}
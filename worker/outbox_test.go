@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	db "github.com/0xThomas3000/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher records every event it's asked to publish, so tests can assert on what
+// the dispatcher actually sent without standing up a real broker.
+type recordingPublisher struct {
+	mu       sync.Mutex
+	received []db.OutboxEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event db.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.received = append(p.received, event)
+	return nil
+}
+
+func (p *recordingPublisher) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.received)
+}
+
+func TestOutboxDispatcherPublishesAndMarksPending(t *testing.T) {
+	store := db.NewMemStore()
+	ctx := context.Background()
+
+	account1, err := store.CreateAccount(ctx, db.CreateAccountParams{Owner: "alice", Balance: 100, Currency: "USD"})
+	require.NoError(t, err)
+
+	account2, err := store.CreateAccount(ctx, db.CreateAccountParams{Owner: "bob", Balance: 0, Currency: "USD"})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        25,
+	})
+	require.NoError(t, err)
+
+	publisher := &recordingPublisher{}
+	dispatcher := NewOutboxDispatcher(store, publisher, 10*time.Millisecond, 10)
+
+	dispatcher.dispatchPending(ctx)
+	require.Equal(t, 1, publisher.count())
+
+	pending, err := store.ListPendingOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, pending, "event should be marked published after a successful dispatch")
+
+	// A second poll must not redeliver the already-published event.
+	dispatcher.dispatchPending(ctx)
+	require.Equal(t, 1, publisher.count())
+}
@@ -0,0 +1,32 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+
+	db "github.com/0xThomas3000/simplebank/db/sqlc"
+)
+
+// RedisStreamPublisher publishes outbox events onto a Redis stream via XADD, so downstream
+// consumers can read them with XREAD/XREADGROUP for fan-out or reliable consumer-group delivery.
+type RedisStreamPublisher struct {
+	client *redis.Client
+	stream string
+}
+
+// NewRedisStreamPublisher creates a publisher that XADDs to stream using client.
+func NewRedisStreamPublisher(client *redis.Client, stream string) *RedisStreamPublisher {
+	return &RedisStreamPublisher{client: client, stream: stream}
+}
+
+func (p *RedisStreamPublisher) Publish(ctx context.Context, event db.OutboxEvent) error {
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.stream,
+		Values: map[string]interface{}{
+			"id":         event.ID,
+			"event_type": event.EventType,
+			"payload":    string(event.Payload),
+		},
+	}).Err()
+}